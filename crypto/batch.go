@@ -0,0 +1,17 @@
+package crypto
+
+// BatchVerifier is implemented by key schemes that can verify many
+// signatures at once more cheaply than verifying them one by one (e.g.
+// via a single multi-scalar multiplication). Schemes without a native
+// batch equation can still satisfy this by falling back to a loop over
+// VerifyBytes.
+//
+// BatchVerify returns the overall result, a per-index result so the
+// caller can tell which signatures are invalid, and an error only when
+// the inputs themselves are malformed (mismatched slice lengths, or a
+// pubkey/signature of a type the scheme doesn't handle). A pubkey or
+// signature that doesn't decode to a valid point or scalar is reported
+// as invalid at its index, not as an error.
+type BatchVerifier interface {
+	BatchVerify(pubKeys []PubKey, msgs [][]byte, sigs []Signature) (bool, []bool, error)
+}
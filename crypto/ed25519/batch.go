@@ -0,0 +1,313 @@
+package ed25519
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// The group/field arithmetic below is self-contained rather than built
+// on github.com/tendermint/ed25519: that package only exports Sign,
+// Verify and MakePublicKey, with no group element or field type that a
+// multi-scalar batch equation could be built from. Everything it does
+// expose (Sign, Verify, MakePublicKey) is still reused as-is, both for
+// key generation elsewhere in this package and for the per-signature
+// fallback below.
+//
+// edPoint is an affine point on the twisted Edwards curve underlying
+// Ed25519, with coordinates reduced mod edP.
+type edPoint struct {
+	X, Y *big.Int
+}
+
+// Curve parameters (RFC 8032): p = 2^255-19, d = -121665/121666 mod p,
+// and L, the order of the base point's prime-order subgroup.
+var (
+	edP          *big.Int
+	edD          *big.Int
+	edL          *big.Int
+	edSqrtMinus1 *big.Int
+	edBasePt     edPoint
+)
+
+func init() {
+	edP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+	lLow, ok := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+	if !ok {
+		panic("ed25519: bad group order constant")
+	}
+	edL = new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 252), lLow)
+
+	edD = fMul(big.NewInt(-121665), fInv(big.NewInt(121666)))
+	edSqrtMinus1 = new(big.Int).Exp(big.NewInt(2), new(big.Int).Rsh(new(big.Int).Sub(edP, big.NewInt(1)), 2), edP)
+
+	// The standard base point has y = 4/5 and the x with even sign.
+	y := fMul(big.NewInt(4), fInv(big.NewInt(5)))
+	x2 := xxFromY(y)
+	x, ok := edSqrt(x2)
+	if !ok {
+		panic("ed25519: failed to derive base point")
+	}
+	if x.Bit(0) != 0 {
+		x = fSub(edP, x)
+	}
+	edBasePt = edPoint{X: x, Y: y}
+}
+
+func fMul(a, b *big.Int) *big.Int { return new(big.Int).Mod(new(big.Int).Mul(a, b), edP) }
+func fAdd(a, b *big.Int) *big.Int { return new(big.Int).Mod(new(big.Int).Add(a, b), edP) }
+func fSub(a, b *big.Int) *big.Int { return new(big.Int).Mod(new(big.Int).Sub(a, b), edP) }
+func fInv(a *big.Int) *big.Int    { return new(big.Int).ModInverse(a, edP) }
+
+// xxFromY returns x^2 for the curve equation -x^2 + y^2 = 1 + d*x^2*y^2,
+// i.e. x^2 = (y^2-1) / (d*y^2+1).
+func xxFromY(y *big.Int) *big.Int {
+	y2 := fMul(y, y)
+	num := fSub(y2, big.NewInt(1))
+	den := fAdd(fMul(edD, y2), big.NewInt(1))
+	return fMul(num, fInv(den))
+}
+
+// edSqrt returns a square root of a mod edP, using that edP == 5 (mod 8).
+func edSqrt(a *big.Int) (*big.Int, bool) {
+	a = new(big.Int).Mod(a, edP)
+	exp := new(big.Int).Rsh(new(big.Int).Add(edP, big.NewInt(3)), 3)
+	cand := new(big.Int).Exp(a, exp, edP)
+	if fMul(cand, cand).Cmp(a) == 0 {
+		return cand, true
+	}
+	cand = fMul(cand, edSqrtMinus1)
+	if fMul(cand, cand).Cmp(a) == 0 {
+		return cand, true
+	}
+	return nil, false
+}
+
+func edIdentity() edPoint { return edPoint{X: big.NewInt(0), Y: big.NewInt(1)} }
+
+// edAdd is the unified Edwards addition law, which is complete (also
+// correct for doubling) for this curve because d is a non-square mod p.
+func edAdd(p1, p2 edPoint) edPoint {
+	x1y2 := fMul(p1.X, p2.Y)
+	y1x2 := fMul(p1.Y, p2.X)
+	y1y2 := fMul(p1.Y, p2.Y)
+	x1x2 := fMul(p1.X, p2.X)
+	dxy := fMul(fMul(edD, x1x2), y1y2)
+
+	xNum, xDen := fAdd(x1y2, y1x2), fAdd(big.NewInt(1), dxy)
+	yNum, yDen := fAdd(y1y2, x1x2), fSub(big.NewInt(1), dxy)
+
+	return edPoint{X: fMul(xNum, fInv(xDen)), Y: fMul(yNum, fInv(yDen))}
+}
+
+// edScalarMult computes k*p with a simple double-and-add, reducing k
+// mod L first. That reduction is only valid when p has order dividing
+// L (i.e. is torsion-free per edIsTorsionFree) -- every edPoint that
+// reaches this function is guaranteed that by edDecode or by being
+// edBasePt itself. This is variable-time, which is fine here: every
+// input to BatchVerify is already public.
+func edScalarMult(p edPoint, k *big.Int) edPoint {
+	return edScalarMultUnreduced(p, new(big.Int).Mod(k, edL))
+}
+
+// edScalarMultUnreduced computes k*p without reducing k mod L first, so
+// it gives the true result even for points outside the prime-order
+// subgroup. It exists for edIsTorsionFree, which needs to multiply by L
+// itself.
+func edScalarMultUnreduced(p edPoint, k *big.Int) edPoint {
+	result := edIdentity()
+	addend := p
+	for i := 0; i < k.BitLen(); i++ {
+		if k.Bit(i) == 1 {
+			result = edAdd(result, addend)
+		}
+		addend = edAdd(addend, addend)
+	}
+	return result
+}
+
+// edIsTorsionFree reports whether p has order dividing L, i.e. lies in
+// the prime-order subgroup generated by edBasePt rather than carrying a
+// component from the curve's order-8 torsion subgroup. The curve's
+// group order is 8L, so L*p lands in the torsion subgroup and is the
+// identity iff p has no torsion component.
+func edIsTorsionFree(p edPoint) bool {
+	q := edScalarMultUnreduced(p, edL)
+	return q.X.Sign() == 0 && q.Y.Cmp(big.NewInt(1)) == 0
+}
+
+// edDecode parses a 32-byte little-endian compressed point and rejects
+// anything outside the prime-order subgroup. That rejection matters for
+// BatchVerify: its batch equation is only equivalent to per-signature
+// Ed25519 verification for points of order L, and accepting a
+// torsion-bearing pubkey or R could let crafted inputs satisfy the
+// batch equation without every component signature actually being
+// valid (see batchVerifyEquation).
+func edDecode(b []byte) (edPoint, bool) {
+	if len(b) != 32 {
+		return edPoint{}, false
+	}
+	signBit := b[31] >> 7
+	yBytes := make([]byte, 32)
+	copy(yBytes, b)
+	yBytes[31] &= 0x7f
+	y := leBytesToBigInt(yBytes)
+	if y.Cmp(edP) >= 0 {
+		return edPoint{}, false
+	}
+	x, ok := edSqrt(xxFromY(y))
+	if !ok {
+		return edPoint{}, false
+	}
+	if uint8(x.Bit(0)) != signBit {
+		x = fSub(edP, x)
+	}
+	p := edPoint{X: x, Y: y}
+	if !edIsTorsionFree(p) {
+		return edPoint{}, false
+	}
+	return p, true
+}
+
+// edEncode serializes p to the 32-byte little-endian compressed form
+// edDecode parses. It exists as edDecode's counterpart for tests.
+func edEncode(p edPoint) []byte {
+	b := bigIntToLEBytes(p.Y, 32)
+	if p.X.Bit(0) != 0 {
+		b[31] |= 0x80
+	}
+	return b
+}
+
+func leBytesToBigInt(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i, v := range b {
+		be[len(b)-1-i] = v
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func bigIntToLEBytes(v *big.Int, n int) []byte {
+	be := v.Bytes()
+	b := make([]byte, n)
+	for i, c := range be {
+		b[len(be)-1-i] = c
+	}
+	return b
+}
+
+// hashRAM computes H(R || A || M) reduced mod L, the scalar used in both
+// single and batch Ed25519 verification.
+func hashRAM(r, a, m []byte) *big.Int {
+	h := sha512.New()
+	h.Write(r)
+	h.Write(a)
+	h.Write(m)
+	return new(big.Int).Mod(leBytesToBigInt(h.Sum(nil)), edL)
+}
+
+// randomScalar128 draws a random 128-bit scalar from a CSPRNG. Batch
+// coefficients must never be derived from the inputs being verified, or
+// an adversary could choose signatures that cancel out in the sum.
+func randomScalar128() *big.Int {
+	return leBytesToBigInt(crypto.CRandBytes(16))
+}
+
+// BatchVerify checks n Ed25519 signatures at once using the standard
+// batch equation:
+//
+//	(Σ z_i·S_i)·B == Σ z_i·R_i + Σ (z_i·H(R_i‖A_i‖M_i))·A_i
+//
+// with independent random 128-bit scalars z_i. edDecode rejects any
+// pubkey or R that carries a torsion component, since the equation
+// above is only equivalent to per-signature verification for points of
+// order L; without that check a crafted torsion-bearing input could
+// satisfy the batch equation while not every component signature is
+// actually valid. If the batch equation does not hold (or an input
+// can't be decoded), BatchVerify falls back to verifying every
+// signature individually so the caller can tell exactly which ones are
+// bad. PubKeyMultisigEd25519.VerifyBytes uses this as its verification
+// path for k-of-n component signatures.
+func BatchVerify(pubKeys []PubKeyEd25519, msgs [][]byte, sigs []SignatureEd25519) (bool, []bool, error) {
+	n := len(pubKeys)
+	if len(msgs) != n || len(sigs) != n {
+		return false, nil, fmt.Errorf("ed25519: BatchVerify: got %d pubKeys, %d msgs, %d sigs, want equal lengths", n, len(msgs), len(sigs))
+	}
+	if n == 0 {
+		return true, []bool{}, nil
+	}
+
+	if ok, err := batchVerifyEquation(pubKeys, msgs, sigs); err == nil && ok {
+		results := make([]bool, n)
+		for i := range results {
+			results[i] = true
+		}
+		return true, results, nil
+	}
+
+	results := make([]bool, n)
+	allOK := true
+	for i := 0; i < n; i++ {
+		results[i] = pubKeys[i].VerifyBytes(msgs[i], sigs[i])
+		allOK = allOK && results[i]
+	}
+	return allOK, results, nil
+}
+
+func batchVerifyEquation(pubKeys []PubKeyEd25519, msgs [][]byte, sigs []SignatureEd25519) (bool, error) {
+	lhs := new(big.Int)
+	rhs := edIdentity()
+
+	for i, pubKey := range pubKeys {
+		A, ok := edDecode(pubKey[:])
+		if !ok {
+			return false, fmt.Errorf("ed25519: BatchVerify: invalid public key at index %d", i)
+		}
+		R, ok := edDecode(sigs[i][:32])
+		if !ok {
+			return false, fmt.Errorf("ed25519: BatchVerify: invalid signature R at index %d", i)
+		}
+		s := leBytesToBigInt(sigs[i][32:])
+		if s.Cmp(edL) >= 0 {
+			return false, fmt.Errorf("ed25519: BatchVerify: signature S out of range at index %d", i)
+		}
+
+		h := hashRAM(sigs[i][:32], pubKey[:], msgs[i])
+		z := randomScalar128()
+
+		lhs.Add(lhs, new(big.Int).Mul(z, s))
+		rhs = edAdd(rhs, edScalarMult(R, z))
+		rhs = edAdd(rhs, edScalarMult(A, new(big.Int).Mod(new(big.Int).Mul(z, h), edL)))
+	}
+
+	lhsPoint := edScalarMult(edBasePt, new(big.Int).Mod(lhs, edL))
+	return lhsPoint.X.Cmp(rhs.X) == 0 && lhsPoint.Y.Cmp(rhs.Y) == 0, nil
+}
+
+var _ crypto.BatchVerifier = Ed25519BatchVerifier{}
+
+// Ed25519BatchVerifier implements crypto.BatchVerifier for PubKeyEd25519.
+type Ed25519BatchVerifier struct{}
+
+func (Ed25519BatchVerifier) BatchVerify(pubKeys []crypto.PubKey, msgs [][]byte, sigs []crypto.Signature) (bool, []bool, error) {
+	edPubKeys := make([]PubKeyEd25519, len(pubKeys))
+	for i, pk := range pubKeys {
+		edPubKey, ok := pk.(PubKeyEd25519)
+		if !ok {
+			return false, nil, fmt.Errorf("ed25519: BatchVerify: pubKey at index %d is not PubKeyEd25519", i)
+		}
+		edPubKeys[i] = edPubKey
+	}
+	edSigs := make([]SignatureEd25519, len(sigs))
+	for i, sig := range sigs {
+		edSig, ok := sig.(SignatureEd25519)
+		if !ok {
+			return false, nil, fmt.Errorf("ed25519: BatchVerify: signature at index %d is not SignatureEd25519", i)
+		}
+		edSigs[i] = edSig
+	}
+	return BatchVerify(edPubKeys, msgs, edSigs)
+}
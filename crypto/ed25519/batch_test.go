@@ -0,0 +1,117 @@
+package ed25519
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBatchVerifyAllValid(t *testing.T) {
+	const n = 5
+	pubKeys := make([]PubKeyEd25519, n)
+	msgs := make([][]byte, n)
+	sigs := make([]SignatureEd25519, n)
+	for i := 0; i < n; i++ {
+		priv := GenPrivKeyEd25519()
+		pubKeys[i] = priv.PubKey().(PubKeyEd25519)
+		msgs[i] = []byte{byte(i), 'm', 's', 'g'}
+		sig, err := priv.Sign(msgs[i])
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		sigs[i] = sig.(SignatureEd25519)
+	}
+
+	ok, results, err := BatchVerify(pubKeys, msgs, sigs)
+	if err != nil {
+		t.Fatalf("BatchVerify: %v", err)
+	}
+	if !ok {
+		t.Fatalf("BatchVerify: got false, want true")
+	}
+	for i, r := range results {
+		if !r {
+			t.Errorf("results[%d] = false, want true", i)
+		}
+	}
+}
+
+func TestBatchVerifyReportsBadIndex(t *testing.T) {
+	const n = 4
+	pubKeys := make([]PubKeyEd25519, n)
+	msgs := make([][]byte, n)
+	sigs := make([]SignatureEd25519, n)
+	for i := 0; i < n; i++ {
+		priv := GenPrivKeyEd25519()
+		pubKeys[i] = priv.PubKey().(PubKeyEd25519)
+		msgs[i] = []byte{byte(i), 'm', 's', 'g'}
+		sig, err := priv.Sign(msgs[i])
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		sigs[i] = sig.(SignatureEd25519)
+	}
+
+	const badIdx = 2
+	msgs[badIdx] = append(append([]byte{}, msgs[badIdx]...), 'x')
+
+	ok, results, err := BatchVerify(pubKeys, msgs, sigs)
+	if err != nil {
+		t.Fatalf("BatchVerify: %v", err)
+	}
+	if ok {
+		t.Fatalf("BatchVerify: got true, want false")
+	}
+	for i, r := range results {
+		want := i != badIdx
+		if r != want {
+			t.Errorf("results[%d] = %v, want %v", i, r, want)
+		}
+	}
+}
+
+func TestBatchVerifyLengthMismatch(t *testing.T) {
+	priv := GenPrivKeyEd25519()
+	pubKey := priv.PubKey().(PubKeyEd25519)
+	sig, err := priv.Sign([]byte("msg"))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	_, _, err = BatchVerify([]PubKeyEd25519{pubKey}, [][]byte{[]byte("msg"), []byte("extra")}, []SignatureEd25519{sig.(SignatureEd25519)})
+	if err == nil {
+		t.Fatal("BatchVerify: got nil error for mismatched slice lengths, want error")
+	}
+}
+
+func TestBatchVerifyEmpty(t *testing.T) {
+	ok, results, err := BatchVerify(nil, nil, nil)
+	if err != nil || !ok || len(results) != 0 {
+		t.Fatalf("BatchVerify(nil, nil, nil) = %v, %v, %v, want true, [], nil", ok, results, err)
+	}
+}
+
+// TestEdDecodeRejectsTorsion is a regression test: edDecode must reject
+// points outside the prime-order subgroup, or BatchVerify's single
+// multi-scalar-multiplication equation stops being equivalent to
+// per-signature verification (see edIsTorsionFree).
+func TestEdDecodeRejectsTorsion(t *testing.T) {
+	// (0, p-1) is the order-2 point on this curve.
+	orderTwo := edPoint{X: big.NewInt(0), Y: new(big.Int).Sub(edP, big.NewInt(1))}
+	if edIsTorsionFree(orderTwo) {
+		t.Fatal("edIsTorsionFree: order-2 point reported torsion-free")
+	}
+
+	if _, ok := edDecode(edEncode(orderTwo)); ok {
+		t.Fatal("edDecode: accepted a point outside the prime-order subgroup")
+	}
+}
+
+func TestEdDecodeEncodeRoundTrip(t *testing.T) {
+	p, ok := edDecode(edEncode(edBasePt))
+	if !ok {
+		t.Fatal("edDecode: rejected the encoded base point")
+	}
+	if p.X.Cmp(edBasePt.X) != 0 || p.Y.Cmp(edBasePt.Y) != 0 {
+		t.Fatal("edDecode(edEncode(edBasePt)) != edBasePt")
+	}
+}
@@ -80,7 +80,13 @@ func (privKey PrivKeyEd25519) ToCurve25519() *[32]byte {
 	return keyCurve25519
 }
 
-// Deterministically generates new priv-key bytes from key.
+// Generate deterministically generates new priv-key bytes from key.
+//
+// Deprecated: this has no chain code or derivation path and reuses the
+// lower 32 bytes of the key in a way that isn't standard Ed25519 key
+// derivation. Use ExtendedPrivKeyEd25519.Derive for SLIP-0010 hardened
+// HD derivation instead. Kept as-is so addresses generated with it
+// don't change.
 func (privKey PrivKeyEd25519) Generate(index int) PrivKeyEd25519 {
 	bz, err := cdc.MarshalBinaryBare(struct {
 		PrivKey [64]byte
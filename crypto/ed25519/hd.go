@@ -0,0 +1,120 @@
+package ed25519
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tendermint/ed25519"
+)
+
+// hardenedOffset is added to an index to mark it hardened, per BIP-32 /
+// SLIP-0010. Ed25519 has no defined public-key-only derivation, so every
+// index derived here must be hardened.
+const hardenedOffset = uint32(1) << 31
+
+// MasterKey derives the SLIP-0010 master key and chain code for Ed25519
+// from a seed, e.g. the output of a BIP-39 mnemonic.
+func MasterKey(seed []byte) (key [32]byte, chainCode [32]byte) {
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	copy(key[:], I[:32])
+	copy(chainCode[:], I[32:])
+	return key, chainCode
+}
+
+// CKDpriv derives the hardened child key and chain code at index i from
+// a parent key and chain code. Ed25519 only supports hardened
+// derivation, so i must be >= hardenedOffset.
+func CKDpriv(kPar [32]byte, cPar [32]byte, i uint32) (key [32]byte, chainCode [32]byte, err error) {
+	if i < hardenedOffset {
+		return key, chainCode, fmt.Errorf("ed25519: CKDpriv: index %d is not hardened, Ed25519 requires index >= 2^31", i)
+	}
+
+	data := make([]byte, 0, 1+32+4)
+	data = append(data, 0x00)
+	data = append(data, kPar[:]...)
+	idx := make([]byte, 4)
+	binary.BigEndian.PutUint32(idx, i)
+	data = append(data, idx...)
+
+	mac := hmac.New(sha512.New, cPar[:])
+	mac.Write(data)
+	I := mac.Sum(nil)
+	copy(key[:], I[:32])
+	copy(chainCode[:], I[32:])
+	return key, chainCode, nil
+}
+
+// ExtendedPrivKeyEd25519 is an Ed25519 private key together with the
+// SLIP-0010 chain code needed to derive further hardened children.
+type ExtendedPrivKeyEd25519 struct {
+	Key       PrivKeyEd25519
+	ChainCode [32]byte
+}
+
+// NewExtendedPrivKeyEd25519 derives the SLIP-0010 master extended key
+// for seed.
+func NewExtendedPrivKeyEd25519(seed []byte) ExtendedPrivKeyEd25519 {
+	key, chainCode := MasterKey(seed)
+	return ExtendedPrivKeyEd25519{Key: privKeyFromSeed(key), ChainCode: chainCode}
+}
+
+// Derive walks a SLIP-0010 path such as "m/44'/118'/0'/0'/0'", applying
+// CKDpriv at each step, and returns the resulting extended key. Every
+// component of the path must be hardened (suffixed with ', h or H).
+func (k ExtendedPrivKeyEd25519) Derive(path string) (ExtendedPrivKeyEd25519, error) {
+	indices, err := parsePath(path)
+	if err != nil {
+		return ExtendedPrivKeyEd25519{}, err
+	}
+
+	var kPar [32]byte
+	copy(kPar[:], k.Key[:32])
+	cPar := k.ChainCode
+
+	for _, i := range indices {
+		kPar, cPar, err = CKDpriv(kPar, cPar, i)
+		if err != nil {
+			return ExtendedPrivKeyEd25519{}, err
+		}
+	}
+	return ExtendedPrivKeyEd25519{Key: privKeyFromSeed(kPar), ChainCode: cPar}, nil
+}
+
+// parsePath parses a BIP-32 / SLIP-0010 style derivation path, e.g.
+// "m/44'/118'/0'/0'/0'", into hardened child indices. A component
+// without a hardened suffix is rejected.
+func parsePath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("ed25519: parsePath: path %q must start with \"m\"", path)
+	}
+
+	indices := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") || strings.HasSuffix(part, "H")
+		if !hardened {
+			return nil, fmt.Errorf("ed25519: parsePath: component %q in path %q is not hardened, Ed25519 requires every index to be hardened", part, path)
+		}
+		n, err := strconv.ParseUint(strings.TrimRight(part, "'hH"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("ed25519: parsePath: invalid component %q in path %q: %v", part, path, err)
+		}
+		indices = append(indices, hardenedOffset+uint32(n))
+	}
+	return indices, nil
+}
+
+// privKeyFromSeed expands a 32-byte Ed25519 seed into the 64-byte
+// (seed || pubkey) form PrivKeyEd25519 is stored as.
+func privKeyFromSeed(seed [32]byte) PrivKeyEd25519 {
+	privKeyBytes := new([64]byte)
+	copy(privKeyBytes[:32], seed[:])
+	ed25519.MakePublicKey(privKeyBytes)
+	return PrivKeyEd25519(*privKeyBytes)
+}
@@ -0,0 +1,129 @@
+package ed25519
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// TestMasterKeySLIP0010Vector1 checks MasterKey against SLIP-0010 Ed25519
+// test vector 1 (seed 000102030405060708090a0b0c0d0e0f), so this
+// implementation interoperates with other SLIP-0010 tooling (Ledger,
+// Cosmos wallets) rather than just being internally self-consistent.
+func TestMasterKeySLIP0010Vector1(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		t.Fatalf("hex.DecodeString: %v", err)
+	}
+
+	wantKey, err := hex.DecodeString("2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7")
+	if err != nil {
+		t.Fatalf("hex.DecodeString: %v", err)
+	}
+	wantChainCode, err := hex.DecodeString("90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb")
+	if err != nil {
+		t.Fatalf("hex.DecodeString: %v", err)
+	}
+
+	key, chainCode := MasterKey(seed)
+	if hex.EncodeToString(key[:]) != hex.EncodeToString(wantKey) {
+		t.Errorf("MasterKey key = %x, want %x", key, wantKey)
+	}
+	if hex.EncodeToString(chainCode[:]) != hex.EncodeToString(wantChainCode) {
+		t.Errorf("MasterKey chainCode = %x, want %x", chainCode, wantChainCode)
+	}
+}
+
+func TestCKDprivRejectsNonHardened(t *testing.T) {
+	var k, c [32]byte
+	if _, _, err := CKDpriv(k, c, hardenedOffset-1); err == nil {
+		t.Fatal("CKDpriv: got nil error for a non-hardened index, want error")
+	}
+}
+
+func TestCKDprivDeterministic(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	kPar, cPar := MasterKey(seed)
+
+	k1, c1, err := CKDpriv(kPar, cPar, hardenedOffset)
+	if err != nil {
+		t.Fatalf("CKDpriv: %v", err)
+	}
+	k2, c2, err := CKDpriv(kPar, cPar, hardenedOffset)
+	if err != nil {
+		t.Fatalf("CKDpriv: %v", err)
+	}
+	if k1 != k2 || c1 != c2 {
+		t.Fatal("CKDpriv: same parent + index produced different children")
+	}
+
+	k3, _, err := CKDpriv(kPar, cPar, hardenedOffset+1)
+	if err != nil {
+		t.Fatalf("CKDpriv: %v", err)
+	}
+	if k1 == k3 {
+		t.Fatal("CKDpriv: different indices produced the same child key")
+	}
+}
+
+// Ed25519 has no public-key-only derivation, so every component of a
+// SLIP-0010 Ed25519 path must be hardened -- unlike the secp256k1 BIP-44
+// convention where the last two levels are usually left non-hardened.
+func TestParsePath(t *testing.T) {
+	indices, err := parsePath("m/44'/118'/0'/0'/0'")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+	want := []uint32{
+		hardenedOffset + 44,
+		hardenedOffset + 118,
+		hardenedOffset + 0,
+		hardenedOffset + 0,
+		hardenedOffset + 0,
+	}
+	if len(indices) != len(want) {
+		t.Fatalf("parsePath: got %d indices, want %d", len(indices), len(want))
+	}
+	for i := range want {
+		if indices[i] != want[i] {
+			t.Errorf("indices[%d] = %d, want %d", i, indices[i], want[i])
+		}
+	}
+}
+
+func TestParsePathRejectsMissingRoot(t *testing.T) {
+	if _, err := parsePath("44'/118'"); err == nil {
+		t.Fatal("parsePath: got nil error for a path not starting with \"m\", want error")
+	}
+}
+
+func TestParsePathRejectsNonHardenedComponent(t *testing.T) {
+	if _, err := parsePath("m/44'/118'/0'/0'/0"); err == nil {
+		t.Fatal("parsePath: got nil error for a non-hardened final component, want error")
+	}
+}
+
+func TestExtendedPrivKeyDeriveDeterministic(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master := NewExtendedPrivKeyEd25519(seed)
+
+	const path = "m/44'/118'/0'/0'/0'"
+	child1, err := master.Derive(path)
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	child2, err := master.Derive(path)
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	if child1.Key != child2.Key || child1.ChainCode != child2.ChainCode {
+		t.Fatal("Derive: same seed + path produced different keys")
+	}
+
+	other, err := master.Derive("m/44'/118'/0'/0'/1'")
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	if child1.Key == other.Key {
+		t.Fatal("Derive: different paths produced the same key")
+	}
+}
@@ -0,0 +1,316 @@
+package ed25519
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tmlibs/common"
+)
+
+const (
+	MultisigEd25519PubKeyAminoRoute    = "tendermint/PubKeyMultisigEd25519"
+	MultisigEd25519SignatureAminoRoute = "tendermint/SignatureMultisigEd25519"
+)
+
+func init() {
+	cdc.RegisterConcrete(PubKeyMultisigEd25519{},
+		MultisigEd25519PubKeyAminoRoute, nil)
+	cdc.RegisterConcrete(SignatureMultisig{},
+		MultisigEd25519SignatureAminoRoute, nil)
+}
+
+// PubKeyMultisigEd25519 and SignatureMultisig implement crypto.PubKey /
+// crypto.Signature and are amino-registered under their own routes, so
+// anything that already decodes a crypto.PubKey/crypto.Signature off
+// the wire (privval, the consensus vote path, evidence handling) picks
+// them up with no further change on its end. This package doesn't
+// contain privval or consensus code itself, so wiring a validator slot
+// up to actually sign and vote with one of these keys is out of scope
+// here.
+
+//-------------------------------------
+
+var _ crypto.PubKey = PubKeyMultisigEd25519{}
+
+// PubKeyMultisigEd25519 is a k-of-n multisig over Ed25519 keys: it is
+// valid for a message iff at least K of PubKeys produced a component
+// signature for it.
+type PubKeyMultisigEd25519 struct {
+	K       uint
+	PubKeys []PubKeyEd25519
+}
+
+// NewPubKeyMultisigEd25519 returns a k-of-n multisig pubkey over
+// pubKeys. pubKeys is sorted by address and deduplicated before being
+// stored, so the resulting Address (and the pubkey's wire bytes) are
+// stable regardless of the order or repetition callers pass keys in,
+// and so a single signer can't occupy more than one threshold slot.
+// It panics if k is 0 or exceeds the number of distinct pubKeys, since
+// either would make the resulting pubkey vacuously or impossibly valid.
+func NewPubKeyMultisigEd25519(k uint, pubKeys []PubKeyEd25519) PubKeyMultisigEd25519 {
+	sorted := make([]PubKeyEd25519, len(pubKeys))
+	copy(sorted, pubKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Address(), sorted[j].Address()) < 0
+	})
+
+	deduped := sorted[:0]
+	for i, pk := range sorted {
+		if i == 0 || !pk.Equals(deduped[len(deduped)-1]) {
+			deduped = append(deduped, pk)
+		}
+	}
+
+	pubKey := PubKeyMultisigEd25519{K: k, PubKeys: deduped}
+	if !pubKey.valid() {
+		panic(fmt.Sprintf("ed25519: NewPubKeyMultisigEd25519: k=%d invalid for %d distinct pubKeys", k, len(deduped)))
+	}
+	return pubKey
+}
+
+// valid reports whether pubKey's own invariants hold: K is in
+// [1, len(PubKeys)], and PubKeys contains no repeated key. These are
+// enforced by NewPubKeyMultisigEd25519, but a PubKeyMultisigEd25519 can
+// also reach this package straight off the wire (amino decodes it field
+// by field, with no hook back into the constructor), so VerifyBytes
+// re-checks this on every call rather than trusting construction.
+func (pubKey PubKeyMultisigEd25519) valid() bool {
+	if pubKey.K == 0 || int(pubKey.K) > len(pubKey.PubKeys) {
+		return false
+	}
+	for i := 1; i < len(pubKey.PubKeys); i++ {
+		for j := 0; j < i; j++ {
+			if pubKey.PubKeys[i].Equals(pubKey.PubKeys[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Address is the SHA256-20 of the amino-encoded sorted pubkey list and
+// K, so it doesn't depend on signing order.
+func (pubKey PubKeyMultisigEd25519) Address() crypto.Address {
+	bz := cdc.MustMarshalBinaryBare(struct {
+		PubKeys []PubKeyEd25519
+		K       uint
+	}{pubKey.PubKeys, pubKey.K})
+	return crypto.Address(tmhash.Sum(bz))
+}
+
+func (pubKey PubKeyMultisigEd25519) Bytes() []byte {
+	return cdc.MustMarshalBinaryBare(pubKey)
+}
+
+// VerifyBytes returns true iff pubKey itself satisfies valid() (see its
+// doc) and sig_ is a SignatureMultisig carrying at least K valid
+// component signatures over msg, each from a distinct pubkey in
+// pubKey.PubKeys (distinct because each is keyed by its position in the
+// bit array).
+func (pubKey PubKeyMultisigEd25519) VerifyBytes(msg []byte, sig_ crypto.Signature) bool {
+	if !pubKey.valid() {
+		return false
+	}
+	sig, ok := sig_.(SignatureMultisig)
+	if !ok {
+		return false
+	}
+	if sig.BitArray == nil || sig.BitArray.Size() != len(pubKey.PubKeys) {
+		return false
+	}
+
+	bitPubKeys := make([]PubKeyEd25519, 0, len(sig.Sigs))
+	bitMsgs := make([][]byte, 0, len(sig.Sigs))
+	sigIdx := 0
+	for i := 0; i < len(pubKey.PubKeys); i++ {
+		if !sig.BitArray.GetIndex(i) {
+			continue
+		}
+		if sigIdx >= len(sig.Sigs) {
+			return false
+		}
+		bitPubKeys = append(bitPubKeys, pubKey.PubKeys[i])
+		bitMsgs = append(bitMsgs, msg)
+		sigIdx++
+	}
+	if sigIdx != len(sig.Sigs) {
+		return false
+	}
+
+	// BatchVerify also reports which component signatures are valid,
+	// which is exactly what we need to count distinct good signers.
+	_, results, err := BatchVerify(bitPubKeys, bitMsgs, sig.Sigs)
+	if err != nil {
+		return false
+	}
+	valid := 0
+	for _, ok := range results {
+		if ok {
+			valid++
+		}
+	}
+	return valid >= int(pubKey.K)
+}
+
+func (pubKey PubKeyMultisigEd25519) String() string {
+	return fmt.Sprintf("PubKeyMultisigEd25519{%d of %d}", pubKey.K, len(pubKey.PubKeys))
+}
+
+func (pubKey PubKeyMultisigEd25519) Equals(other crypto.PubKey) bool {
+	otherMulti, ok := other.(PubKeyMultisigEd25519)
+	if !ok || otherMulti.K != pubKey.K || len(otherMulti.PubKeys) != len(pubKey.PubKeys) {
+		return false
+	}
+	for i, pk := range pubKey.PubKeys {
+		if !pk.Equals(otherMulti.PubKeys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//-------------------------------------
+
+var _ crypto.Signature = SignatureMultisig{}
+
+// SignatureMultisig is a set of component Ed25519 signatures for a
+// PubKeyMultisigEd25519, together with a bit array recording which of
+// the pubkey's PubKeys each component signature is from.
+type SignatureMultisig struct {
+	BitArray *common.BitArray
+	Sigs     []SignatureEd25519
+}
+
+func (sig SignatureMultisig) Bytes() []byte {
+	return cdc.MustMarshalBinaryBare(sig)
+}
+
+func (sig SignatureMultisig) IsZero() bool { return sig.BitArray == nil || len(sig.Sigs) == 0 }
+
+func (sig SignatureMultisig) String() string {
+	return fmt.Sprintf("SignatureMultisig{%v}", sig.BitArray)
+}
+
+func (sig SignatureMultisig) Equals(other crypto.Signature) bool {
+	otherMulti, ok := other.(SignatureMultisig)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(sig.Bytes(), otherMulti.Bytes())
+}
+
+//-------------------------------------
+
+// MultisigBuilder accumulates partial (component) signatures for a
+// PubKeyMultisigEd25519 over a fixed message as they arrive from peers,
+// so they can be combined into a single SignatureMultisig once K of them
+// have been collected.
+type MultisigBuilder struct {
+	PubKey PubKeyMultisigEd25519
+	Msg    []byte
+	sigs   map[int]SignatureEd25519 // signer index -> component signature
+}
+
+// NewMultisigBuilder starts a fresh builder for pubKey signing msg.
+func NewMultisigBuilder(pubKey PubKeyMultisigEd25519, msg []byte) *MultisigBuilder {
+	return &MultisigBuilder{
+		PubKey: pubKey,
+		Msg:    msg,
+		sigs:   make(map[int]SignatureEd25519),
+	}
+}
+
+// AddSignature verifies sig against the i'th pubkey and, if it's valid,
+// records it. Adding a second signature for the same index overwrites
+// the first rather than accumulating a duplicate.
+func (b *MultisigBuilder) AddSignature(i int, sig SignatureEd25519) error {
+	if i < 0 || i >= len(b.PubKey.PubKeys) {
+		return fmt.Errorf("ed25519: MultisigBuilder: signer index %d out of range [0,%d)", i, len(b.PubKey.PubKeys))
+	}
+	if !b.PubKey.PubKeys[i].VerifyBytes(b.Msg, sig) {
+		return fmt.Errorf("ed25519: MultisigBuilder: signature from signer %d does not verify", i)
+	}
+	b.sigs[i] = sig
+	return nil
+}
+
+// Count returns the number of distinct signer indices collected so far.
+func (b *MultisigBuilder) Count() int { return len(b.sigs) }
+
+// Signature assembles the signatures collected so far into a
+// SignatureMultisig. It fails if fewer than PubKey.K have been
+// collected.
+func (b *MultisigBuilder) Signature() (SignatureMultisig, error) {
+	if len(b.sigs) < int(b.PubKey.K) {
+		return SignatureMultisig{}, fmt.Errorf("ed25519: MultisigBuilder: have %d of %d required signatures", len(b.sigs), b.PubKey.K)
+	}
+
+	indices := b.sortedIndices()
+	bitArray := common.NewBitArray(len(b.PubKey.PubKeys))
+	sigs := make([]SignatureEd25519, 0, len(indices))
+	for _, i := range indices {
+		bitArray.SetIndex(i, true)
+		sigs = append(sigs, b.sigs[i])
+	}
+	return SignatureMultisig{BitArray: bitArray, Sigs: sigs}, nil
+}
+
+func (b *MultisigBuilder) sortedIndices() []int {
+	indices := make([]int, 0, len(b.sigs))
+	for i := range b.sigs {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// partialMultisigState is the wire representation of a MultisigBuilder's
+// progress, so offline co-signers can pass it around and round-trip it.
+type partialMultisigState struct {
+	PubKey  PubKeyMultisigEd25519
+	Msg     []byte
+	Indices []int
+	Sigs    []SignatureEd25519
+}
+
+// MarshalBinary serializes the builder's current state: the pubkey, the
+// message being signed, and the partial signatures collected so far.
+func (b *MultisigBuilder) MarshalBinary() ([]byte, error) {
+	indices := b.sortedIndices()
+	sigs := make([]SignatureEd25519, len(indices))
+	for j, i := range indices {
+		sigs[j] = b.sigs[i]
+	}
+	return cdc.MarshalBinaryBare(partialMultisigState{
+		PubKey:  b.PubKey,
+		Msg:     b.Msg,
+		Indices: indices,
+		Sigs:    sigs,
+	})
+}
+
+// UnmarshalMultisigBuilder deserializes a MultisigBuilder previously
+// produced by MarshalBinary, so another co-signer can keep adding to
+// it. Every carried signature is re-verified via AddSignature, the same
+// as if it had just arrived from a peer, so a tampered-with or stale
+// blob can't inject a signature that was never actually valid.
+func UnmarshalMultisigBuilder(bz []byte) (*MultisigBuilder, error) {
+	var state partialMultisigState
+	if err := cdc.UnmarshalBinaryBare(bz, &state); err != nil {
+		return nil, err
+	}
+	if len(state.Indices) != len(state.Sigs) {
+		return nil, fmt.Errorf("ed25519: UnmarshalMultisigBuilder: %d indices but %d sigs", len(state.Indices), len(state.Sigs))
+	}
+
+	b := NewMultisigBuilder(state.PubKey, state.Msg)
+	for j, i := range state.Indices {
+		if err := b.AddSignature(i, state.Sigs[j]); err != nil {
+			return nil, fmt.Errorf("ed25519: UnmarshalMultisigBuilder: %v", err)
+		}
+	}
+	return b, nil
+}
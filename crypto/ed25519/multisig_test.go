@@ -0,0 +1,274 @@
+package ed25519
+
+import (
+	"testing"
+
+	"github.com/tendermint/tmlibs/common"
+)
+
+func genMultisigKeys(t *testing.T, n int) []PrivKeyEd25519 {
+	t.Helper()
+	privs := make([]PrivKeyEd25519, n)
+	for i := range privs {
+		privs[i] = GenPrivKeyEd25519()
+	}
+	return privs
+}
+
+func pubKeysOf(privs []PrivKeyEd25519) []PubKeyEd25519 {
+	pubs := make([]PubKeyEd25519, len(privs))
+	for i, p := range privs {
+		pubs[i] = p.PubKey().(PubKeyEd25519)
+	}
+	return pubs
+}
+
+func TestMultisigVerifyBytesThreshold(t *testing.T) {
+	privs := genMultisigKeys(t, 3)
+	pubKey := NewPubKeyMultisigEd25519(2, pubKeysOf(privs))
+	msg := []byte("block vote")
+
+	b := NewMultisigBuilder(pubKey, msg)
+	for _, i := range []int{0, 2} {
+		sig, err := privs[i].Sign(msg)
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		if err := b.AddSignature(indexOf(t, pubKey, privs[i]), sig.(SignatureEd25519)); err != nil {
+			t.Fatalf("AddSignature: %v", err)
+		}
+	}
+	sig, err := b.Signature()
+	if err != nil {
+		t.Fatalf("Signature: %v", err)
+	}
+	if !pubKey.VerifyBytes(msg, sig) {
+		t.Fatal("VerifyBytes: 2-of-3 signature with 2 valid signers should verify")
+	}
+}
+
+func TestMultisigBuilderSignatureBelowThreshold(t *testing.T) {
+	privs := genMultisigKeys(t, 3)
+	pubKey := NewPubKeyMultisigEd25519(2, pubKeysOf(privs))
+	msg := []byte("block vote")
+
+	b := NewMultisigBuilder(pubKey, msg)
+	sig0, err := privs[0].Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := b.AddSignature(indexOf(t, pubKey, privs[0]), sig0.(SignatureEd25519)); err != nil {
+		t.Fatalf("AddSignature: %v", err)
+	}
+	if _, err := b.Signature(); err == nil {
+		t.Fatal("Signature: got nil error with only 1 of 2 required signatures, want error")
+	}
+}
+
+// TestNewPubKeyMultisigEd25519RejectsKeyReuse is a regression test for
+// the bug fixed alongside this type's introduction: a duplicated pubkey
+// must not be able to occupy more than one threshold slot, or a single
+// signer could satisfy a k-of-n threshold alone.
+func TestNewPubKeyMultisigEd25519RejectsKeyReuse(t *testing.T) {
+	privs := genMultisigKeys(t, 1)
+	pub := privs[0].PubKey().(PubKeyEd25519)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewPubKeyMultisigEd25519: got no panic for K=2 with a single distinct pubkey repeated twice, want panic")
+		}
+	}()
+	NewPubKeyMultisigEd25519(2, []PubKeyEd25519{pub, pub})
+}
+
+// TestNewPubKeyMultisigEd25519DedupsKeys checks that a duplicated pubkey
+// collapses to one slot instead of being rejected outright, as long as K
+// still fits the resulting distinct set.
+func TestNewPubKeyMultisigEd25519DedupsKeys(t *testing.T) {
+	privs := genMultisigKeys(t, 1)
+	pub := privs[0].PubKey().(PubKeyEd25519)
+
+	pubKey := NewPubKeyMultisigEd25519(1, []PubKeyEd25519{pub, pub})
+	if len(pubKey.PubKeys) != 1 {
+		t.Fatalf("NewPubKeyMultisigEd25519: got %d distinct pubKeys, want 1", len(pubKey.PubKeys))
+	}
+}
+
+// TestNewPubKeyMultisigEd25519RejectsZeroK is a regression test: K=0
+// would make VerifyBytes accept any message with zero component
+// signatures.
+func TestNewPubKeyMultisigEd25519RejectsZeroK(t *testing.T) {
+	privs := genMultisigKeys(t, 2)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewPubKeyMultisigEd25519: got no panic for K=0, want panic")
+		}
+	}()
+	NewPubKeyMultisigEd25519(0, pubKeysOf(privs))
+}
+
+// TestUnmarshalMultisigBuilderRejectsUnverifiedSignature is a regression
+// test: a partialMultisigState carrying a signature that doesn't verify
+// (e.g. corrupted or forged in transit) must be rejected rather than
+// silently accepted into the rebuilt MultisigBuilder.
+func TestUnmarshalMultisigBuilderRejectsUnverifiedSignature(t *testing.T) {
+	privs := genMultisigKeys(t, 2)
+	pubKey := NewPubKeyMultisigEd25519(2, pubKeysOf(privs))
+	msg := []byte("block vote")
+
+	sig, err := privs[0].Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	forged := sig.(SignatureEd25519)
+	forged[0] ^= 0xff // corrupt the signature so it no longer verifies
+
+	bz, err := cdc.MarshalBinaryBare(partialMultisigState{
+		PubKey:  pubKey,
+		Msg:     msg,
+		Indices: []int{indexOf(t, pubKey, privs[0])},
+		Sigs:    []SignatureEd25519{forged},
+	})
+	if err != nil {
+		t.Fatalf("MarshalBinaryBare: %v", err)
+	}
+
+	b, err := UnmarshalMultisigBuilder(bz)
+	if err == nil {
+		t.Fatalf("UnmarshalMultisigBuilder: got nil error for a forged signature, want error (builder count = %d)", b.Count())
+	}
+}
+
+func TestMultisigBuilderMarshalRoundTrip(t *testing.T) {
+	privs := genMultisigKeys(t, 3)
+	pubKey := NewPubKeyMultisigEd25519(2, pubKeysOf(privs))
+	msg := []byte("block vote")
+
+	b := NewMultisigBuilder(pubKey, msg)
+	sig, err := privs[0].Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := b.AddSignature(indexOf(t, pubKey, privs[0]), sig.(SignatureEd25519)); err != nil {
+		t.Fatalf("AddSignature: %v", err)
+	}
+
+	bz, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	restored, err := UnmarshalMultisigBuilder(bz)
+	if err != nil {
+		t.Fatalf("UnmarshalMultisigBuilder: %v", err)
+	}
+	if restored.Count() != 1 {
+		t.Fatalf("restored.Count() = %d, want 1", restored.Count())
+	}
+
+	sig2, err := privs[1].Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := restored.AddSignature(indexOf(t, pubKey, privs[1]), sig2.(SignatureEd25519)); err != nil {
+		t.Fatalf("AddSignature: %v", err)
+	}
+
+	finalSig, err := restored.Signature()
+	if err != nil {
+		t.Fatalf("Signature: %v", err)
+	}
+	if !pubKey.VerifyBytes(msg, finalSig) {
+		t.Fatal("VerifyBytes: signature assembled after a marshal round-trip should verify")
+	}
+}
+
+// TestMultisigVerifyBytesRejectsForgedSignature exercises VerifyBytes
+// directly with a SignatureMultisig carrying a component signature that
+// was never checked by MultisigBuilder.AddSignature.
+func TestMultisigVerifyBytesRejectsForgedSignature(t *testing.T) {
+	privs := genMultisigKeys(t, 2)
+	pubKey := NewPubKeyMultisigEd25519(2, pubKeysOf(privs))
+	msg := []byte("block vote")
+
+	goodSig, err := privs[0].Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	forged := goodSig.(SignatureEd25519)
+	forged[0] ^= 0xff
+
+	bitArray := common.NewBitArray(len(pubKey.PubKeys))
+	bitArray.SetIndex(0, true)
+	bitArray.SetIndex(1, true)
+	sig := SignatureMultisig{
+		BitArray: bitArray,
+		Sigs:     []SignatureEd25519{forged, forged},
+	}
+
+	if pubKey.VerifyBytes(msg, sig) {
+		t.Fatal("VerifyBytes: accepted a SignatureMultisig with a forged component signature")
+	}
+}
+
+// TestPubKeyMultisigEd25519VerifyBytesRejectsWireZeroK is a regression
+// test for a PubKeyMultisigEd25519 that reaches VerifyBytes having
+// skipped NewPubKeyMultisigEd25519 entirely -- exactly what amino
+// produces when decoding one off the wire, since it populates fields by
+// reflection with no constructor hook. K=0 must not make VerifyBytes
+// trivially true for zero component signatures.
+func TestPubKeyMultisigEd25519VerifyBytesRejectsWireZeroK(t *testing.T) {
+	privs := genMultisigKeys(t, 2)
+	wirePubKey := PubKeyMultisigEd25519{K: 0, PubKeys: pubKeysOf(privs)}
+	msg := []byte("block vote")
+
+	sig := SignatureMultisig{
+		BitArray: common.NewBitArray(len(wirePubKey.PubKeys)),
+		Sigs:     []SignatureEd25519{},
+	}
+	if wirePubKey.VerifyBytes(msg, sig) {
+		t.Fatal("VerifyBytes: accepted a wire-constructed pubkey with K=0 and zero signatures")
+	}
+}
+
+// TestPubKeyMultisigEd25519VerifyBytesRejectsWireDuplicateKeys mirrors
+// TestPubKeyMultisigEd25519VerifyBytesRejectsWireZeroK for the other
+// invariant NewPubKeyMultisigEd25519 enforces: without it, a
+// wire-constructed pubkey that repeats one signer's key across two
+// slots would let a single signature (replayed into both bit
+// positions) satisfy a K=2 threshold alone.
+func TestPubKeyMultisigEd25519VerifyBytesRejectsWireDuplicateKeys(t *testing.T) {
+	privs := genMultisigKeys(t, 1)
+	pub := privs[0].PubKey().(PubKeyEd25519)
+	wirePubKey := PubKeyMultisigEd25519{K: 2, PubKeys: []PubKeyEd25519{pub, pub}}
+	msg := []byte("block vote")
+
+	sig, err := privs[0].Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	edSig := sig.(SignatureEd25519)
+
+	bitArray := common.NewBitArray(2)
+	bitArray.SetIndex(0, true)
+	bitArray.SetIndex(1, true)
+	multiSig := SignatureMultisig{BitArray: bitArray, Sigs: []SignatureEd25519{edSig, edSig}}
+
+	if wirePubKey.VerifyBytes(msg, multiSig) {
+		t.Fatal("VerifyBytes: accepted a wire-constructed pubkey with a duplicated signer key")
+	}
+}
+
+// indexOf finds priv's slot in pubKey.PubKeys (NewPubKeyMultisigEd25519
+// sorts and dedups, so callers can't assume input order survives).
+func indexOf(t *testing.T, pubKey PubKeyMultisigEd25519, priv PrivKeyEd25519) int {
+	t.Helper()
+	pub := priv.PubKey().(PubKeyEd25519)
+	for i, pk := range pubKey.PubKeys {
+		if pk.Equals(pub) {
+			return i
+		}
+	}
+	t.Fatalf("indexOf: priv's pubkey not found in multisig pubkey")
+	return -1
+}